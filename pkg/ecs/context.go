@@ -0,0 +1,57 @@
+package ecs
+
+import (
+	"fmt"
+	"os"
+)
+
+// ecsDebug enables Context's signature checks. It is read once at
+// startup from ECS_DEBUG=1 so production builds pay no per-call cost.
+var ecsDebug = os.Getenv("ECS_DEBUG") == "1"
+
+// declarer is the part of ISystem that Context needs: a name for error
+// messages and a way to check whether a component id was declared. Query
+// implements it too, so Query.Each can hand out a Context the same way
+// ISystem.Update does.
+type declarer interface {
+	GetName() string
+	declares(componentID int) bool
+}
+
+// Context is handed to ISystem.Update (and Query.Each) for every entity
+// being iterated. It replaces passing a throwaway zero-value Component
+// into Registry.GetComponent just to identify a type: callers instead
+// resolve a ComponentID once (e.g. in a system's constructor) and fetch
+// with Context.Component.
+type Context struct {
+	registry *Registry
+	system   declarer
+
+	// Entity is the entity currently being iterated.
+	Entity Entity
+}
+
+func newContext(registry *Registry, system declarer, entity Entity) *Context {
+	return &Context{
+		registry: registry,
+		system:   system,
+		Entity:   entity,
+	}
+}
+
+// Component returns the component identified by id for ctx.Entity, or
+// nil if the entity doesn't have it. With ECS_DEBUG=1 it panics if the
+// calling system never declared id via RequireComponent/OptionalComponent,
+// catching systems that read state outside their declared signature.
+func (ctx *Context) Component(id int) Component {
+	if ecsDebug && !ctx.system.declares(id) {
+		panic(fmt.Sprintf("ecs: system %q read component id %d without declaring it", ctx.system.GetName(), id))
+	}
+	return ctx.registry.getComponentByID(id, ctx.Entity.GetID())
+}
+
+// Remove kills ctx.Entity. The entity stops being iterated once the
+// Registry's next Update flushes entitiesToBeKilled.
+func (ctx *Context) Remove() {
+	ctx.registry.KillEntity(ctx.Entity)
+}
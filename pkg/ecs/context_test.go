@@ -0,0 +1,110 @@
+package ecs
+
+import "testing"
+
+func TestContext_ComponentReturnsDeclaredComponent(t *testing.T) {
+	reg := newTestRegistry(t)
+	posID := componentTypeID[testPosition](reg)
+
+	sys := NewBaseSystem("TestSystem")
+	sys.RequireComponent(posID)
+
+	e := reg.CreateEntity()
+	Add(reg, e, testPosition{X: 1, Y: 2})
+	reg.Update()
+
+	ctx := newContext(reg, sys, e)
+	component := ctx.Component(posID)
+	pos, ok := component.(testPosition)
+	if !ok {
+		t.Fatalf("ctx.Component(posID): got %T, want testPosition", component)
+	}
+	if pos.X != 1 || pos.Y != 2 {
+		t.Fatalf("ctx.Component(posID): got %+v, want {1 2}", pos)
+	}
+}
+
+func TestContext_ComponentReturnsNilWhenEntityLacksIt(t *testing.T) {
+	reg := newTestRegistry(t)
+	posID := componentTypeID[testPosition](reg)
+
+	sys := NewBaseSystem("TestSystem")
+	sys.OptionalComponent(posID)
+
+	e := reg.CreateEntity()
+	reg.Update()
+
+	ctx := newContext(reg, sys, e)
+	if got := ctx.Component(posID); got != nil {
+		t.Fatalf("ctx.Component(posID): got %v, want nil", got)
+	}
+}
+
+// testSystem is the smallest ISystem: BaseSystem's bookkeeping plus a
+// no-op Update, so it can be registered with Registry.AddSystem.
+type testSystem struct {
+	*BaseSystem
+}
+
+func (s testSystem) Update(ctx *Context, dt float64) {}
+
+func TestContext_Remove(t *testing.T) {
+	reg := newTestRegistry(t)
+	posID := componentTypeID[testPosition](reg)
+
+	sys := testSystem{NewBaseSystem("TestSystem")}
+	sys.RequireComponent(posID)
+	reg.AddSystem(sys)
+
+	e := reg.CreateEntity()
+	Add(reg, e, testPosition{X: 1, Y: 2})
+	reg.Update()
+
+	found := false
+	for _, entity := range sys.GetSystemEntities() {
+		if entity.GetID() == e.GetID() {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("system does not track entity before Remove")
+	}
+
+	ctx := newContext(reg, sys, e)
+	ctx.Remove()
+	reg.Update()
+
+	for _, entity := range sys.GetSystemEntities() {
+		if entity.GetID() == e.GetID() {
+			t.Fatalf("system still tracks entity %d after ctx.Remove + Update", e.GetID())
+		}
+	}
+}
+
+// TestContext_DebugModePanicsOnUndeclaredRead exercises ECS_DEBUG's check
+// directly by flipping the package-level ecsDebug flag, since it's normally
+// latched once from the environment at package init.
+func TestContext_DebugModePanicsOnUndeclaredRead(t *testing.T) {
+	prev := ecsDebug
+	ecsDebug = true
+	defer func() { ecsDebug = prev }()
+
+	reg := newTestRegistry(t)
+	posID := componentTypeID[testPosition](reg)
+	velID := componentTypeID[testVelocity](reg)
+
+	sys := NewBaseSystem("TestSystem")
+	sys.RequireComponent(posID)
+
+	e := reg.CreateEntity()
+	Add(reg, e, testPosition{X: 1, Y: 2})
+	reg.Update()
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatalf("ctx.Component(velID): expected panic reading undeclared component, got none")
+		}
+	}()
+	ctx := newContext(reg, sys, e)
+	ctx.Component(velID)
+}
@@ -3,6 +3,7 @@ package ecs
 import (
 	"container/list"
 	"fmt"
+	"sync"
 
 	"github.com/kubil6y/go_game_engine/internal/type_registry"
 	"github.com/kubil6y/go_game_engine/internal/utils"
@@ -32,8 +33,23 @@ type Registry struct {
 	numEntities int
 	// [index = entity id]
 	entityComponentSignatures []*bitset.Bitset32
-	// [index = component id] [index = entity id]
-	componentPools        []*[]Component
+	// [index = component id], each holding the *typedPool[T] for that
+	// component type; see Add/Get/Has/Remove in generic.go.
+	componentPools []any
+	// [index = component id], a type-erased view onto componentPools so
+	// a component can be read back by ComponentID alone (Context,
+	// Snapshot) without the caller knowing T.
+	componentBoxers []func(entityID int) (Component, bool)
+	// [index = component id], the write-side counterpart of
+	// componentBoxers; Restore uses it to put a decoded component back
+	// without knowing its type at compile time. It returns an error if
+	// the decoded Component doesn't actually hold the type the pool was
+	// created for.
+	componentSetters []func(entityID int, c Component) error
+	// [index = component id], clears all entries of a typed pool;
+	// Restore uses it to reset existing state before replaying a
+	// snapshot.
+	componentResetters    []func()
 	systems               map[int]ISystem
 	entitiesToBeAdded     set.Set[Entity]
 	entitiesToBeKilled    set.Set[Entity]
@@ -41,13 +57,28 @@ type Registry struct {
 	logger                *logger.Logger
 	componentTypeRegistry *type_registry.TypeRegistry
 	systemTypeRegistry    *type_registry.TypeRegistry
+	eventBus              *EventBus
+	// queryCache holds Query.Each results keyed by their (with, without)
+	// component id pair; see invalidateQueryCache in query.go.
+	queryCache map[string][]Entity
+	// componentTypeIDs caches the ComponentID assigned to each component
+	// type the first time Add/Get/Has/Remove/RegisterCodec sees it, so
+	// repeated calls skip the reflect.TypeOf + type registry round trip.
+	// It is keyed per-Registry: two Registry instances in the same
+	// process must be free to assign the same type different ids (or
+	// different types the same id), so this cannot be a package-level
+	// cache shared across every Registry.
+	componentTypeIDs sync.Map // map[reflect.Type]int
 }
 
 func NewRegistry(maxComponentCount int, logger *logger.Logger, componentTypeRegistry *type_registry.TypeRegistry, systemTypeRegistry *type_registry.TypeRegistry) *Registry {
 	return &Registry{
 		numEntities:               0,
 		entityComponentSignatures: make([]*bitset.Bitset32, 10),
-		componentPools:            make([]*[]Component, 10),
+		componentPools:            make([]any, 10),
+		componentBoxers:           make([]func(int) (Component, bool), 10),
+		componentSetters:          make([]func(int, Component) error, 10),
+		componentResetters:        make([]func(), 10),
 		systems:                   make(map[int]ISystem),
 		entitiesToBeAdded:         set.New[Entity](),
 		entitiesToBeKilled:        set.New[Entity](),
@@ -55,6 +86,8 @@ func NewRegistry(maxComponentCount int, logger *logger.Logger, componentTypeRegi
 		logger:                    logger,
 		componentTypeRegistry:     componentTypeRegistry,
 		systemTypeRegistry:        systemTypeRegistry,
+		eventBus:                  NewEventBus(),
+		queryCache:                make(map[string][]Entity),
 	}
 }
 
@@ -62,6 +95,10 @@ func (r *Registry) GetComponentTypeRegistry() *type_registry.TypeRegistry {
 	return r.componentTypeRegistry
 }
 
+func (r *Registry) GetEventBus() *EventBus {
+	return r.eventBus
+}
+
 // ENTITY MANAGEMENT ////////////////////
 func (r *Registry) CreateEntity() Entity {
 	var entityID int
@@ -69,10 +106,16 @@ func (r *Registry) CreateEntity() Entity {
 		r.numEntities++
 		entityID = r.numEntities
 		if entityID >= len(r.entityComponentSignatures) {
-			utils.ResizeArray(r.entityComponentSignatures, entityID+1)
-			for i := len(r.entityComponentSignatures); i <= entityID; i++ {
+			oldLen := len(r.entityComponentSignatures)
+			r.entityComponentSignatures = utils.ResizeArray(r.entityComponentSignatures, entityID+1)
+			for i := oldLen; i <= entityID; i++ {
 				r.entityComponentSignatures[i] = bitset.NewBitset32()
 			}
+		} else if r.entityComponentSignatures[entityID] == nil {
+			// Slots within the initial capacity are never visited by the
+			// grow branch above; they start out as nil *Bitset32 and must
+			// be initialized here on first use.
+			r.entityComponentSignatures[entityID] = bitset.NewBitset32()
 		}
 	} else {
 		frontElement := r.freeIDs.Front()
@@ -82,63 +125,36 @@ func (r *Registry) CreateEntity() Entity {
 	entity := NewEntity(entityID)
 	r.entitiesToBeAdded.Add(entity)
 	r.logger.Info(fmt.Sprintf("Entity created with id = %d", entityID), nil)
+	r.invalidateQueryCache()
 	return entity
 }
 
 func (r *Registry) KillEntity(entity Entity) {
 	r.logger.Info(fmt.Sprintf("Entity killed with id = %d", entity.GetID()), nil)
 	r.entitiesToBeKilled.Add(entity)
+	r.invalidateQueryCache()
 }
 
 // COMPONENT MANAGEMENT ////////////////////
-func (r *Registry) AddComponent(entity Entity, component Component) error {
-	entityID := entity.GetID()
-	componentID, err := r.componentTypeRegistry.Register(component)
-	if err != nil {
-		switch err {
-		case type_registry.ErrNilItem:
-			panic("can not register null item")
-		case type_registry.ErrMaxItemsExceeded:
-			panic("too many types registered!")
-		default:
-			return nil
-		}
-	}
-
-	if componentID >= len(r.componentPools) {
-		newSize := componentID + 1
-		r.componentPools = utils.ResizeArray(r.componentPools, newSize)
-	}
-
-	if r.componentPools[componentID] == nil {
-		newComponentPool := make([]Component, r.numEntities)
-		r.componentPools[componentID] = &newComponentPool
+//
+// Component storage is managed through the generic Add/Get/Has/Remove
+// helpers in generic.go, which give callers compile-time type safety
+// instead of passing a throwaway zero-value Component to identify a
+// type. getComponentByID below is the one type-erased escape hatch,
+// used where the caller only has a runtime ComponentID (Context).
+
+// getComponentByID fetches a component by its already-resolved
+// ComponentID, for callers that don't know T at compile time. It backs
+// Context.Component.
+func (r *Registry) getComponentByID(componentID int, entityID int) Component {
+	if componentID < 0 || componentID >= len(r.componentBoxers) || r.componentBoxers[componentID] == nil {
+		return nil
 	}
-
-	componentPool := r.componentPools[componentID]
-	if entityID >= len(*componentPool) {
-		newSize := entityID + 1 // Resize to at least accommodate the new entityID
-		*componentPool = utils.ResizeArray(*componentPool, newSize)
+	component, ok := r.componentBoxers[componentID](entityID)
+	if !ok {
+		return nil
 	}
-	(*componentPool)[entityID] = component
-	r.logger.Info(fmt.Sprintf("%s registered with id: %d", component, componentID), nil)
-	return nil
-}
-
-func (r *Registry) RemoveComponent(entity Entity, component Component) {
-	panic("TODO")
-}
-
-func (r *Registry) HasComponent(entity Entity, component Component) bool {
-	panic("TODO")
-}
-
-func (r *Registry) GetComponent(entity Entity, component Component) Component {
-	componentID, err := r.componentTypeRegistry.Get(component)
-	if err != nil {
-		r.logger.Error(err, fmt.Sprintf("Registry failed to add [%s] to entity id %d", component, entity.GetID()), nil)
-	}
-	return (*r.componentPools[componentID])[entity.GetID()]
+	return component
 }
 
 // SYSTEM MANAGEMENT ////////////////////
@@ -174,3 +190,58 @@ func (r *Registry) HasSystem(systemID int) bool {
 	_, exists := r.systems[systemID]
 	return exists
 }
+
+// Update is called once per frame. It flushes entities created/killed
+// during the previous frame and, for every entity whose signature could
+// have changed, re-evaluates which systems should be tracking it. This
+// keeps ISystem.GetSystemEntities current so Update can loop over only
+// the entities a system actually cares about. EntityCreated/EntityKilled
+// are published on the event bus as each entity is flushed.
+func (r *Registry) Update() {
+	for _, entity := range r.entitiesToBeAdded.Items() {
+		r.updateEntitySystemMembership(entity)
+		Publish(r.eventBus, EntityCreated{Entity: entity})
+	}
+	r.entitiesToBeAdded.Clear()
+
+	for _, entity := range r.entitiesToBeKilled.Items() {
+		r.removeEntityFromSystems(entity)
+		r.entityComponentSignatures[entity.GetID()] = bitset.NewBitset32()
+		r.freeIDs.PushBack(entity.GetID())
+		Publish(r.eventBus, EntityKilled{Entity: entity})
+	}
+	r.entitiesToBeKilled.Clear()
+	r.invalidateQueryCache()
+}
+
+// updateEntitySystemMembership adds or removes entity from every
+// registered system depending on whether its current component
+// signature matches that system's required signature.
+func (r *Registry) updateEntitySystemMembership(entity Entity) {
+	entitySignature := r.entityComponentSignatures[entity.GetID()]
+	for _, system := range r.systems {
+		if entitySignature.Matches(system.GetSignature()) {
+			system.AddEntityToSystem(entity)
+		} else {
+			system.RemoveEntityFromSystem(entity)
+		}
+	}
+}
+
+func (r *Registry) removeEntityFromSystems(entity Entity) {
+	for _, system := range r.systems {
+		system.RemoveEntityFromSystem(entity)
+	}
+}
+
+// UpdateSystems runs every registered system's Update once per entity it
+// is currently tracking, handing each call a Context scoped to that
+// entity. Call Update first each frame so membership reflects the
+// previous frame's component/entity changes.
+func (r *Registry) UpdateSystems(dt float64) {
+	for _, system := range r.systems {
+		for _, entity := range system.GetSystemEntities() {
+			system.Update(newContext(r, system, entity), dt)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package ecs
+
+import "reflect"
+
+// EventBus lets systems publish and subscribe to typed events without
+// coupling to each other. Registry owns one instance and emits the
+// built-in lifecycle events: EntityCreated and EntityKilled from Update
+// as it flushes pending entities, ComponentAdded[T] and
+// ComponentRemoved[T] directly from Add[T] and Remove[T]. Game code can
+// publish/subscribe to its own event types (CollisionEvent, DamageEvent,
+// ...) the same way. Publish dispatches at the moment it is called;
+// events are not queued for a later flush.
+type EventBus struct {
+	handlers map[reflect.Type][]func(any)
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{
+		handlers: make(map[reflect.Type][]func(any)),
+	}
+}
+
+// Subscribe registers fn to run every time an event of type T is
+// published on bus.
+func Subscribe[T any](bus *EventBus, fn func(T)) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	bus.handlers[t] = append(bus.handlers[t], func(event any) {
+		fn(event.(T))
+	})
+}
+
+// Publish dispatches event synchronously to every subscriber registered
+// for type T, in subscription order.
+func Publish[T any](bus *EventBus, event T) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	for _, handler := range bus.handlers[t] {
+		handler(event)
+	}
+}
+
+// EntityCreated is published when Update flushes an entity that was
+// created during the previous frame.
+type EntityCreated struct {
+	Entity Entity
+}
+
+// EntityKilled is published when Update flushes an entity that was
+// killed during the previous frame.
+type EntityKilled struct {
+	Entity Entity
+}
+
+// ComponentAdded is published after Add[T] attaches a component of type
+// T to Entity.
+type ComponentAdded[T Component] struct {
+	Entity    Entity
+	Component T
+}
+
+// ComponentRemoved is published after Remove[T] detaches a component of
+// type T from Entity.
+type ComponentRemoved[T Component] struct {
+	Entity Entity
+}
@@ -0,0 +1,86 @@
+package ecs
+
+import "testing"
+
+type testDamageEvent struct {
+	Amount int
+}
+
+func TestSubscribePublish(t *testing.T) {
+	bus := NewEventBus()
+	var got testDamageEvent
+	calls := 0
+	Subscribe(bus, func(e testDamageEvent) {
+		got = e
+		calls++
+	})
+
+	Publish(bus, testDamageEvent{Amount: 5})
+
+	if calls != 1 {
+		t.Fatalf("handler called %d times, want 1", calls)
+	}
+	if got.Amount != 5 {
+		t.Fatalf("got %+v, want Amount 5", got)
+	}
+}
+
+func TestPublish_MultipleSubscribersInOrder(t *testing.T) {
+	bus := NewEventBus()
+	var order []int
+	Subscribe(bus, func(e testDamageEvent) { order = append(order, 1) })
+	Subscribe(bus, func(e testDamageEvent) { order = append(order, 2) })
+
+	Publish(bus, testDamageEvent{Amount: 1})
+
+	if len(order) != 2 || order[0] != 1 || order[1] != 2 {
+		t.Fatalf("got %v, want [1 2]", order)
+	}
+}
+
+func TestPublish_NoSubscribersIsNoop(t *testing.T) {
+	bus := NewEventBus()
+	Publish(bus, testDamageEvent{Amount: 1}) // must not panic
+}
+
+func TestRegistry_PublishesEntityCreatedAndKilledOnUpdate(t *testing.T) {
+	reg := newTestRegistry(t)
+	var created, killed []Entity
+	Subscribe(reg.GetEventBus(), func(e EntityCreated) { created = append(created, e.Entity) })
+	Subscribe(reg.GetEventBus(), func(e EntityKilled) { killed = append(killed, e.Entity) })
+
+	e := reg.CreateEntity()
+	reg.Update()
+
+	if len(created) != 1 || created[0].GetID() != e.GetID() {
+		t.Fatalf("EntityCreated: got %v, want [%v]", created, e)
+	}
+
+	reg.KillEntity(e)
+	reg.Update()
+
+	if len(killed) != 1 || killed[0].GetID() != e.GetID() {
+		t.Fatalf("EntityKilled: got %v, want [%v]", killed, e)
+	}
+}
+
+func TestRegistry_PublishesComponentAddedAndRemoved(t *testing.T) {
+	reg := newTestRegistry(t)
+	var added []testPosition
+	var removed int
+	Subscribe(reg.GetEventBus(), func(e ComponentAdded[testPosition]) { added = append(added, e.Component) })
+	Subscribe(reg.GetEventBus(), func(e ComponentRemoved[testPosition]) { removed++ })
+
+	e := reg.CreateEntity()
+	Add(reg, e, testPosition{X: 1, Y: 2})
+
+	if len(added) != 1 || added[0].X != 1 || added[0].Y != 2 {
+		t.Fatalf("ComponentAdded: got %v, want [{1 2}]", added)
+	}
+
+	Remove[testPosition](reg, e)
+
+	if removed != 1 {
+		t.Fatalf("ComponentRemoved: got %d events, want 1", removed)
+	}
+}
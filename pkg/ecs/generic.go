@@ -0,0 +1,189 @@
+package ecs
+
+import (
+	"encoding/gob"
+	"fmt"
+	"reflect"
+
+	"github.com/kubil6y/go_game_engine/internal/type_registry"
+	"github.com/kubil6y/go_game_engine/internal/utils"
+)
+
+// componentTypeID resolves (and registers, on first use) the
+// ComponentID for T within r. The cache lives on r (not a package
+// var): two independent Registry instances are free to assign T
+// different ids, so a process-wide cache would let the first Registry
+// to see T silently dictate the id every other Registry uses for it.
+func componentTypeID[T Component](r *Registry) int {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if id, ok := r.componentTypeIDs.Load(t); ok {
+		return id.(int)
+	}
+
+	var zero T
+	id, err := r.componentTypeRegistry.Register(zero)
+	if err != nil {
+		switch err {
+		case type_registry.ErrNilItem:
+			panic("can not register null item")
+		case type_registry.ErrMaxItemsExceeded:
+			panic("too many types registered!")
+		default:
+			// Every later Add/Get/Has/Remove[T] on this registry trusts
+			// whatever id gets cached next, so an unrecognized registry
+			// error must not fall through to caching a bogus id.
+			panic(fmt.Sprintf("ecs: could not register component type %T: %v", zero, err))
+		}
+	}
+	r.componentTypeIDs.Store(t, id)
+	return id
+}
+
+// typedPool is the per-component-type backing store: a plain []T plus a
+// parallel presence slice, so a zero-valued T and "entity has no
+// component" stay distinguishable.
+type typedPool[T Component] struct {
+	items []T
+	has   []bool
+}
+
+func (p *typedPool[T]) get(entityID int) (T, bool) {
+	if entityID < 0 || entityID >= len(p.items) || !p.has[entityID] {
+		var zero T
+		return zero, false
+	}
+	return p.items[entityID], true
+}
+
+func (p *typedPool[T]) getBoxed(entityID int) (Component, bool) {
+	return p.get(entityID)
+}
+
+func (p *typedPool[T]) set(entityID int, c T) {
+	p.grow(entityID + 1)
+	p.items[entityID] = c
+	p.has[entityID] = true
+}
+
+func (p *typedPool[T]) clear(entityID int) {
+	if entityID < 0 || entityID >= len(p.has) {
+		return
+	}
+	var zero T
+	p.items[entityID] = zero
+	p.has[entityID] = false
+}
+
+func (p *typedPool[T]) reset() {
+	p.items = nil
+	p.has = nil
+}
+
+// setBoxed stores c at entityID, returning an error instead of storing
+// anything if c doesn't actually hold a T. It backs
+// Registry.componentSetters, the write-side counterpart of getBoxed used
+// by Restore to route a gob-decoded Component back into its typed pool;
+// Restore's input is serialized data that may be stale, corrupt, or
+// produced by a different codec registration order, so this cannot
+// assume the assertion succeeds.
+func (p *typedPool[T]) setBoxed(entityID int, c Component) error {
+	v, ok := c.(T)
+	if !ok {
+		var zero T
+		return fmt.Errorf("ecs: component type mismatch: expected %T, got %T", zero, c)
+	}
+	p.set(entityID, v)
+	return nil
+}
+
+func (p *typedPool[T]) grow(size int) {
+	if size <= len(p.items) {
+		return
+	}
+	items := make([]T, size)
+	copy(items, p.items)
+	p.items = items
+
+	has := make([]bool, size)
+	copy(has, p.has)
+	p.has = has
+}
+
+// getTypedPool returns (creating if necessary) the typedPool[T] backing
+// componentID, registering it with componentBoxers/componentSetters/
+// componentResetters so it can also be read, written, and cleared by id
+// alone (Context, Restore).
+func getTypedPool[T Component](r *Registry, componentID int) *typedPool[T] {
+	if componentID >= len(r.componentPools) {
+		size := componentID + 1
+		r.componentPools = utils.ResizeArray(r.componentPools, size)
+		r.componentBoxers = utils.ResizeArray(r.componentBoxers, size)
+		r.componentSetters = utils.ResizeArray(r.componentSetters, size)
+		r.componentResetters = utils.ResizeArray(r.componentResetters, size)
+	}
+	if r.componentPools[componentID] == nil {
+		pool := &typedPool[T]{}
+		r.componentPools[componentID] = pool
+		r.componentBoxers[componentID] = pool.getBoxed
+		r.componentSetters[componentID] = pool.setBoxed
+		r.componentResetters[componentID] = pool.reset
+	}
+	return r.componentPools[componentID].(*typedPool[T])
+}
+
+// RegisterCodec registers T as a serializable component type: with gob,
+// so Component interface values of type T decode back to their concrete
+// type, and with r, so Restore can route a decoded value into T's typed
+// pool purely from its ComponentID. Call it once per component type at
+// startup, before any Snapshot/Restore.
+func RegisterCodec[T Component](r *Registry) {
+	var zero T
+	gob.Register(zero)
+	componentID := componentTypeID[T](r)
+	getTypedPool[T](r, componentID)
+}
+
+// Add attaches component c of type T to entity e, creating T's backing
+// pool on first use.
+func Add[T Component](r *Registry, e Entity, c T) {
+	entityID := e.GetID()
+	componentID := componentTypeID[T](r)
+	pool := getTypedPool[T](r, componentID)
+	pool.set(entityID, c)
+	r.entityComponentSignatures[entityID].Set(componentID)
+	r.updateEntitySystemMembership(e)
+	r.logger.Info(fmt.Sprintf("%s registered with id: %d", c, componentID), nil)
+	r.invalidateQueryCache()
+	Publish(r.eventBus, ComponentAdded[T]{Entity: e, Component: c})
+}
+
+// Get returns entity e's component of type T and whether it has one.
+func Get[T Component](r *Registry, e Entity) (T, bool) {
+	componentID := componentTypeID[T](r)
+	pool := getTypedPool[T](r, componentID)
+	return pool.get(e.GetID())
+}
+
+// Has reports whether entity e has a component of type T.
+func Has[T Component](r *Registry, e Entity) bool {
+	entityID := e.GetID()
+	componentID := componentTypeID[T](r)
+	if entityID < 0 || entityID >= len(r.entityComponentSignatures) {
+		return false
+	}
+	return r.entityComponentSignatures[entityID].Test(componentID)
+}
+
+// Remove detaches entity e's component of type T, if it has one.
+func Remove[T Component](r *Registry, e Entity) {
+	entityID := e.GetID()
+	componentID := componentTypeID[T](r)
+	had := r.entityComponentSignatures[entityID].Test(componentID)
+	getTypedPool[T](r, componentID).clear(entityID)
+	r.entityComponentSignatures[entityID].Clear(componentID)
+	r.updateEntitySystemMembership(e)
+	r.invalidateQueryCache()
+	if had {
+		Publish(r.eventBus, ComponentRemoved[T]{Entity: e})
+	}
+}
@@ -0,0 +1,99 @@
+package ecs
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/kubil6y/go_game_engine/internal/type_registry"
+	"github.com/kubil6y/go_game_engine/pkg/logger"
+)
+
+// testPosition and testVelocity are minimal Component implementations used
+// across generic_test.go and snapshot_test.go.
+type testPosition struct {
+	X, Y float64
+}
+
+func (p testPosition) GetID() (int, error) { return 0, nil }
+func (p testPosition) String() string      { return fmt.Sprintf("Position{%v,%v}", p.X, p.Y) }
+
+type testVelocity struct {
+	DX, DY float64
+}
+
+func (v testVelocity) GetID() (int, error) { return 0, nil }
+func (v testVelocity) String() string      { return fmt.Sprintf("Velocity{%v,%v}", v.DX, v.DY) }
+
+// newTestRegistry builds a fresh, independently-typed Registry: each call
+// gets its own componentTypeRegistry/systemTypeRegistry, so two registries
+// in the same test are free to assign the same component type different
+// ComponentIDs.
+func newTestRegistry(t *testing.T) *Registry {
+	t.Helper()
+	return NewRegistry(
+		32,
+		logger.NewLogger(),
+		type_registry.NewTypeRegistry(32),
+		type_registry.NewTypeRegistry(32),
+	)
+}
+
+// TestAdd_MultipleRegistriesAssignIndependentComponentIDs guards against a
+// Registry-crossing ComponentID cache: regA sees testPosition first (id 0),
+// while regB sees testVelocity first, so testPosition ends up at a
+// different id in regB. Add/Get must still route each type to its own
+// pool in every registry, regardless of registration order.
+func TestAdd_MultipleRegistriesAssignIndependentComponentIDs(t *testing.T) {
+	regA := newTestRegistry(t)
+	eA := regA.CreateEntity()
+	Add(regA, eA, testPosition{X: 1, Y: 2})
+
+	regB := newTestRegistry(t)
+	eB := regB.CreateEntity()
+	Add(regB, eB, testVelocity{DX: 3, DY: 4})
+	Add(regB, eB, testPosition{X: 5, Y: 6})
+
+	pos, ok := Get[testPosition](regB, eB)
+	if !ok {
+		t.Fatalf("Get[testPosition] on regB: not found")
+	}
+	if pos.X != 5 || pos.Y != 6 {
+		t.Fatalf("Get[testPosition] on regB: got %+v, want {5 6}", pos)
+	}
+
+	vel, ok := Get[testVelocity](regB, eB)
+	if !ok {
+		t.Fatalf("Get[testVelocity] on regB: not found")
+	}
+	if vel.DX != 3 || vel.DY != 4 {
+		t.Fatalf("Get[testVelocity] on regB: got %+v, want {3 4}", vel)
+	}
+
+	// regA's own testPosition must be unaffected by regB's registrations.
+	aPos, ok := Get[testPosition](regA, eA)
+	if !ok || aPos.X != 1 || aPos.Y != 2 {
+		t.Fatalf("Get[testPosition] on regA: got %+v, ok=%v, want {1 2}", aPos, ok)
+	}
+}
+
+func TestAddGetHasRemove(t *testing.T) {
+	reg := newTestRegistry(t)
+	e := reg.CreateEntity()
+
+	if Has[testPosition](reg, e) {
+		t.Fatalf("Has[testPosition] before Add: got true, want false")
+	}
+
+	Add(reg, e, testPosition{X: 1, Y: 2})
+	if !Has[testPosition](reg, e) {
+		t.Fatalf("Has[testPosition] after Add: got false, want true")
+	}
+
+	Remove[testPosition](reg, e)
+	if Has[testPosition](reg, e) {
+		t.Fatalf("Has[testPosition] after Remove: got true, want false")
+	}
+	if _, ok := Get[testPosition](reg, e); ok {
+		t.Fatalf("Get[testPosition] after Remove: got ok=true, want false")
+	}
+}
@@ -0,0 +1,114 @@
+package ecs
+
+import (
+	"fmt"
+
+	"github.com/kubil6y/go_game_engine/pkg/bitset"
+)
+
+// Query is an ad-hoc alternative to declaring a full ISystem: With and
+// Without build a required/excluded bitset.Bitset32 pair, and Each
+// iterates only the live entities whose signature matches it, handing
+// each one a Context just like ISystem.Update does.
+type Query struct {
+	registry   *Registry
+	withIDs    []int
+	withoutIDs []int
+	with       *bitset.Bitset32
+}
+
+// Query starts building a new ad-hoc query against r's live entities.
+func (r *Registry) Query() *Query {
+	return &Query{
+		registry: r,
+		with:     bitset.NewBitset32(),
+	}
+}
+
+// With requires every entity the query matches to have all of
+// componentIDs.
+func (q *Query) With(componentIDs ...int) *Query {
+	for _, id := range componentIDs {
+		q.withIDs = append(q.withIDs, id)
+		q.with.Set(id)
+	}
+	return q
+}
+
+// Without excludes any entity that has one or more of componentIDs.
+func (q *Query) Without(componentIDs ...int) *Query {
+	q.withoutIDs = append(q.withoutIDs, componentIDs...)
+	return q
+}
+
+func (q *Query) GetName() string {
+	return "Query"
+}
+
+func (q *Query) declares(componentID int) bool {
+	if q.with.Test(componentID) {
+		return true
+	}
+	for _, id := range q.withoutIDs {
+		if id == componentID {
+			return true
+		}
+	}
+	return false
+}
+
+// Each runs fn for every live entity currently matching the query. The
+// matching entity list is cached on the Registry by (with, without)
+// pair and reused until a component or entity change invalidates it.
+func (q *Query) Each(fn func(entity Entity, ctx *Context)) {
+	r := q.registry
+	key := fmt.Sprintf("%v|%v", q.withIDs, q.withoutIDs)
+
+	entities, ok := r.queryCache[key]
+	if !ok {
+		entities = r.evaluateQuery(q.with, q.withoutIDs)
+		r.queryCache[key] = entities
+	}
+
+	for _, entity := range entities {
+		fn(entity, newContext(r, q, entity))
+	}
+}
+
+func (r *Registry) evaluateQuery(with *bitset.Bitset32, withoutIDs []int) []Entity {
+	free := make(map[int]bool)
+	for e := r.freeIDs.Front(); e != nil; e = e.Next() {
+		free[e.Value.(int)] = true
+	}
+
+	var matched []Entity
+	for entityID, signature := range r.entityComponentSignatures {
+		// Entity ids start at 1; freed ids keep an empty, non-nil
+		// signature that an empty With() would otherwise match.
+		if entityID == 0 || entityID > r.numEntities || free[entityID] {
+			continue
+		}
+		if signature == nil || !signature.Matches(with) {
+			continue
+		}
+		excluded := false
+		for _, id := range withoutIDs {
+			if signature.Test(id) {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			matched = append(matched, NewEntity(entityID))
+		}
+	}
+	return matched
+}
+
+// invalidateQueryCache drops every cached query result. It's called
+// whenever Add/Remove, CreateEntity/KillEntity, Update's flush of killed
+// entities, or Restore could have changed which entities match a live
+// query.
+func (r *Registry) invalidateQueryCache() {
+	r.queryCache = make(map[string][]Entity)
+}
@@ -0,0 +1,115 @@
+package ecs
+
+import "testing"
+
+func TestQuery_With(t *testing.T) {
+	reg := newTestRegistry(t)
+	posID := componentTypeID[testPosition](reg)
+
+	withPos := reg.CreateEntity()
+	Add(reg, withPos, testPosition{X: 1, Y: 2})
+
+	withoutPos := reg.CreateEntity()
+	reg.Update()
+
+	var matched []Entity
+	reg.Query().With(posID).Each(func(entity Entity, ctx *Context) {
+		matched = append(matched, entity)
+	})
+
+	if len(matched) != 1 || matched[0].GetID() != withPos.GetID() {
+		t.Fatalf("Query().With(posID): got %v, want [%v]", matched, withPos)
+	}
+	_ = withoutPos
+}
+
+func TestQuery_Without(t *testing.T) {
+	reg := newTestRegistry(t)
+	posID := componentTypeID[testPosition](reg)
+	velID := componentTypeID[testVelocity](reg)
+
+	posOnly := reg.CreateEntity()
+	Add(reg, posOnly, testPosition{X: 1, Y: 2})
+
+	posAndVel := reg.CreateEntity()
+	Add(reg, posAndVel, testPosition{X: 3, Y: 4})
+	Add(reg, posAndVel, testVelocity{DX: 1, DY: 1})
+	reg.Update()
+
+	var matched []Entity
+	reg.Query().With(posID).Without(velID).Each(func(entity Entity, ctx *Context) {
+		matched = append(matched, entity)
+	})
+
+	if len(matched) != 1 || matched[0].GetID() != posOnly.GetID() {
+		t.Fatalf("Query().With(posID).Without(velID): got %v, want [%v]", matched, posOnly)
+	}
+}
+
+// TestQuery_CacheInvalidatedOnCreateEntity guards against a stale
+// Query.Each result set: an empty With() matches every live entity, so a
+// new entity created after the first Each call must still show up on the
+// next one.
+func TestQuery_CacheInvalidatedOnCreateEntity(t *testing.T) {
+	reg := newTestRegistry(t)
+	query := reg.Query()
+
+	first := reg.CreateEntity()
+	reg.Update()
+
+	var matched []Entity
+	query.Each(func(entity Entity, ctx *Context) {
+		matched = append(matched, entity)
+	})
+	if len(matched) != 1 || matched[0].GetID() != first.GetID() {
+		t.Fatalf("Each before second CreateEntity: got %v, want [%v]", matched, first)
+	}
+
+	second := reg.CreateEntity()
+	reg.Update()
+
+	matched = nil
+	query.Each(func(entity Entity, ctx *Context) {
+		matched = append(matched, entity)
+	})
+	found := false
+	for _, e := range matched {
+		if e.GetID() == second.GetID() {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("Each after second CreateEntity: got %v, missing entity %v created after the query was first cached", matched, second)
+	}
+}
+
+func TestQuery_CacheInvalidatedOnAddRemove(t *testing.T) {
+	reg := newTestRegistry(t)
+	posID := componentTypeID[testPosition](reg)
+
+	e := reg.CreateEntity()
+	reg.Update()
+
+	query := reg.Query().With(posID)
+	var matched []Entity
+	query.Each(func(entity Entity, ctx *Context) { matched = append(matched, entity) })
+	if len(matched) != 0 {
+		t.Fatalf("Each before Add: got %v, want none", matched)
+	}
+
+	Add(reg, e, testPosition{X: 1, Y: 2})
+
+	matched = nil
+	query.Each(func(entity Entity, ctx *Context) { matched = append(matched, entity) })
+	if len(matched) != 1 || matched[0].GetID() != e.GetID() {
+		t.Fatalf("Each after Add: got %v, want [%v]", matched, e)
+	}
+
+	Remove[testPosition](reg, e)
+
+	matched = nil
+	query.Each(func(entity Entity, ctx *Context) { matched = append(matched, entity) })
+	if len(matched) != 0 {
+		t.Fatalf("Each after Remove: got %v, want none", matched)
+	}
+}
@@ -0,0 +1,140 @@
+package ecs
+
+import (
+	"bytes"
+	"container/list"
+	"encoding/gob"
+	"fmt"
+
+	"github.com/kubil6y/go_game_engine/pkg/bitset"
+	"github.com/kubil6y/go_game_engine/pkg/set"
+)
+
+// snapshotComponent is one (entity, component) pair, gob-encoded through
+// the Component interface. Decoding Data back into a concrete type
+// requires that type to have been registered with RegisterCodec first.
+type snapshotComponent struct {
+	ComponentID int
+	EntityID    int
+	Data        []byte
+}
+
+// snapshotData is the full serialized form of a Registry's world state.
+// Component signatures are intentionally not stored directly; they're
+// rebuilt from which components each entity has. ComponentIDs, however,
+// are used verbatim on Restore: the Registry restoring a snapshot must
+// have registered (via RegisterCodec) the same component types in the
+// same order as the Registry that produced it, or Restore returns an
+// error rather than silently mixing up types.
+type snapshotData struct {
+	NumEntities   int
+	LiveEntityIDs []int
+	FreeIDs       []int
+	Components    []snapshotComponent
+}
+
+// Snapshot serializes every live entity, its components, and enough
+// bookkeeping (numEntities, free entity ids) to deterministically
+// reconstruct the world with Restore. Component types must have been
+// registered with RegisterCodec beforehand.
+func (r *Registry) Snapshot() ([]byte, error) {
+	var snap snapshotData
+	snap.NumEntities = r.numEntities
+
+	free := make(map[int]bool)
+	for e := r.freeIDs.Front(); e != nil; e = e.Next() {
+		id := e.Value.(int)
+		snap.FreeIDs = append(snap.FreeIDs, id)
+		free[id] = true
+	}
+
+	for entityID := 1; entityID <= r.numEntities; entityID++ {
+		if free[entityID] {
+			continue
+		}
+		snap.LiveEntityIDs = append(snap.LiveEntityIDs, entityID)
+
+		for componentID, getBoxed := range r.componentBoxers {
+			if getBoxed == nil {
+				continue
+			}
+			component, ok := getBoxed(entityID)
+			if !ok {
+				continue
+			}
+
+			var buf bytes.Buffer
+			if err := gob.NewEncoder(&buf).Encode(&component); err != nil {
+				return nil, fmt.Errorf("ecs: snapshot entity %d component %d: %w", entityID, componentID, err)
+			}
+			snap.Components = append(snap.Components, snapshotComponent{
+				ComponentID: componentID,
+				EntityID:    entityID,
+				Data:        buf.Bytes(),
+			})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(snap); err != nil {
+		return nil, fmt.Errorf("ecs: snapshot: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Restore replaces r's world state with the one encoded in data,
+// rebuilding entityComponentSignatures, componentPools, and freeIDs from
+// scratch. Component types referenced by data must already have been
+// registered with RegisterCodec.
+func (r *Registry) Restore(data []byte) error {
+	var snap snapshotData
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&snap); err != nil {
+		return fmt.Errorf("ecs: restore: %w", err)
+	}
+
+	for _, reset := range r.componentResetters {
+		if reset != nil {
+			reset()
+		}
+	}
+
+	r.numEntities = snap.NumEntities
+	r.entityComponentSignatures = make([]*bitset.Bitset32, snap.NumEntities+1)
+	for i := range r.entityComponentSignatures {
+		r.entityComponentSignatures[i] = bitset.NewBitset32()
+	}
+
+	r.freeIDs = list.New()
+	for _, id := range snap.FreeIDs {
+		r.freeIDs.PushBack(id)
+	}
+
+	for _, c := range snap.Components {
+		if c.ComponentID >= len(r.componentSetters) || r.componentSetters[c.ComponentID] == nil {
+			return fmt.Errorf("ecs: restore: component id %d has no registered codec (call RegisterCodec before Restore)", c.ComponentID)
+		}
+
+		var component Component
+		if err := gob.NewDecoder(bytes.NewReader(c.Data)).Decode(&component); err != nil {
+			return fmt.Errorf("ecs: restore entity %d component %d: %w", c.EntityID, c.ComponentID, err)
+		}
+		if err := r.componentSetters[c.ComponentID](c.EntityID, component); err != nil {
+			return fmt.Errorf("ecs: restore entity %d component %d: %w", c.EntityID, c.ComponentID, err)
+		}
+		r.entityComponentSignatures[c.EntityID].Set(c.ComponentID)
+	}
+
+	r.entitiesToBeAdded = set.New[Entity]()
+	r.entitiesToBeKilled = set.New[Entity]()
+	for _, system := range r.systems {
+		for _, entity := range system.GetSystemEntities() {
+			system.RemoveEntityFromSystem(entity)
+		}
+	}
+	for _, id := range snap.LiveEntityIDs {
+		r.updateEntitySystemMembership(NewEntity(id))
+	}
+
+	r.invalidateQueryCache()
+	return nil
+}
@@ -0,0 +1,64 @@
+package ecs
+
+import "testing"
+
+func TestSnapshotRestore_RoundTrip(t *testing.T) {
+	src := newTestRegistry(t)
+	RegisterCodec[testPosition](src)
+	RegisterCodec[testVelocity](src)
+
+	e := src.CreateEntity()
+	Add(src, e, testPosition{X: 1, Y: 2})
+	Add(src, e, testVelocity{DX: 3, DY: 4})
+	src.Update()
+
+	data, err := src.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+
+	dst := newTestRegistry(t)
+	RegisterCodec[testPosition](dst)
+	RegisterCodec[testVelocity](dst)
+
+	if err := dst.Restore(data); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+
+	pos, ok := Get[testPosition](dst, e)
+	if !ok || pos.X != 1 || pos.Y != 2 {
+		t.Fatalf("Get[testPosition] after Restore: got %+v, ok=%v, want {1 2}", pos, ok)
+	}
+	vel, ok := Get[testVelocity](dst, e)
+	if !ok || vel.DX != 3 || vel.DY != 4 {
+		t.Fatalf("Get[testVelocity] after Restore: got %+v, ok=%v, want {3 4}", vel, ok)
+	}
+}
+
+// TestRestore_ComponentTypeMismatchReturnsError covers a snapshot produced
+// by a Registry that registered component codecs in a different order than
+// the Registry restoring it: the same ComponentID then refers to a
+// different concrete type in each, and Restore must report that as an
+// error instead of panicking on the componentSetters type assertion.
+func TestRestore_ComponentTypeMismatchReturnsError(t *testing.T) {
+	src := newTestRegistry(t)
+	RegisterCodec[testPosition](src)
+	RegisterCodec[testVelocity](src)
+
+	e := src.CreateEntity()
+	Add(src, e, testPosition{X: 1, Y: 2})
+	src.Update()
+
+	data, err := src.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+
+	dst := newTestRegistry(t)
+	RegisterCodec[testVelocity](dst)
+	RegisterCodec[testPosition](dst)
+
+	if err := dst.Restore(data); err == nil {
+		t.Fatalf("Restore: got nil error, want a type-mismatch error")
+	}
+}
@@ -0,0 +1,92 @@
+package ecs
+
+import (
+	"github.com/kubil6y/go_game_engine/pkg/bitset"
+)
+
+// ISystem is implemented by every gameplay system the Registry manages.
+// A system declares the components it cares about via RequireComponent
+// (exposed through GetSignature) and the Registry keeps GetSystemEntities
+// up to date as entities are created, killed, or have their components
+// change, so Update only ever has to loop over entities that actually
+// match.
+type ISystem interface {
+	GetName() string
+	GetSignature() *bitset.Bitset32
+	GetSystemEntities() []Entity
+	AddEntityToSystem(entity Entity)
+	RemoveEntityFromSystem(entity Entity)
+	Update(ctx *Context, dt float64)
+
+	// declares reports whether componentID is part of this system's
+	// required or optional signature. It backs Context's debug-mode
+	// check and is satisfied automatically by embedding BaseSystem.
+	declares(componentID int) bool
+}
+
+// BaseSystem implements the bookkeeping shared by every ISystem so
+// concrete systems only need to embed it, declare their components in
+// their constructor via RequireComponent/OptionalComponent, and
+// implement Update.
+type BaseSystem struct {
+	name           string
+	signature      *bitset.Bitset32
+	optional       *bitset.Bitset32
+	systemEntities []Entity
+}
+
+func NewBaseSystem(name string) *BaseSystem {
+	return &BaseSystem{
+		name:      name,
+		signature: bitset.NewBitset32(),
+		optional:  bitset.NewBitset32(),
+	}
+}
+
+func (s *BaseSystem) GetName() string {
+	return s.name
+}
+
+// RequireComponent marks componentID as part of this system's signature.
+// Only entities whose own signature contains every required component
+// are added to GetSystemEntities.
+func (s *BaseSystem) RequireComponent(componentID int) {
+	s.signature.Set(componentID)
+}
+
+func (s *BaseSystem) GetSignature() *bitset.Bitset32 {
+	return s.signature
+}
+
+// OptionalComponent marks componentID as something this system is
+// allowed to read via ctx.Component, without requiring every matched
+// entity to have it.
+func (s *BaseSystem) OptionalComponent(componentID int) {
+	s.optional.Set(componentID)
+}
+
+func (s *BaseSystem) declares(componentID int) bool {
+	return s.signature.Test(componentID) || s.optional.Test(componentID)
+}
+
+func (s *BaseSystem) GetSystemEntities() []Entity {
+	return s.systemEntities
+}
+
+func (s *BaseSystem) AddEntityToSystem(entity Entity) {
+	for _, e := range s.systemEntities {
+		if e.GetID() == entity.GetID() {
+			return
+		}
+	}
+	s.systemEntities = append(s.systemEntities, entity)
+}
+
+func (s *BaseSystem) RemoveEntityFromSystem(entity Entity) {
+	for i, e := range s.systemEntities {
+		if e.GetID() == entity.GetID() {
+			s.systemEntities = append(s.systemEntities[:i], s.systemEntities[i+1:]...)
+			return
+		}
+	}
+}